@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Event names emitted by InfoShare's write paths via stub.SetEvent. Off-chain services can
+// subscribe to these through the Fabric event hub (block or chaincode event listeners)
+// instead of polling readInfo.
+const (
+	EventInfoCreated     = "InfoCreated"
+	EventInfoUpdated     = "InfoUpdated"
+	EventInfoTransferred = "InfoTransferred"
+	EventInfoDeleted     = "InfoDeleted"
+)
+
+// infoEvent is the JSON payload for every InfoShare chaincode event. Fabric only allows one
+// event per transaction, so every write path - however many steps it takes internally -
+// consolidates its outcome into a single infoEvent.
+type infoEvent struct {
+	InfoID     string `json:"InfoID"`
+	InfoType   string `json:"InfoType"`
+	Department string `json:"Department"`
+	Uploader   string `json:"Uploader"`
+	TxID       string `json:"TxID"`
+}
+
+// setInfoEvent marshals an infoEvent and emits it under eventName via stub.SetEvent
+func setInfoEvent(stub shim.ChaincodeStubInterface, eventName string, InfoID string, InfoType string, Department string, Uploader string) error {
+	event := &infoEvent{
+		InfoID:     InfoID,
+		InfoType:   InfoType,
+		Department: Department,
+		Uploader:   Uploader,
+		TxID:       stub.GetTxID(),
+	}
+
+	eventAsBytes, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return stub.SetEvent(eventName, eventAsBytes)
+}
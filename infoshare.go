@@ -2,26 +2,120 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
 )
 
 type InfoShare struct {
 }
 
+// info is the public metadata record, kept on the channel ledger via PutState. The actual
+// Content lives off-channel in a private data collection (see infoPrivateDetails) and is
+// represented here only by its hash, so tampering with the private payload is detectable.
 type info struct {
-	ObjectType string `json:"docType"`
-	InfoID     string `json:"InfoID"`
-	InfoType   string `json:"InfoType"`
-	Content    string `json:"Content"`
-	UploadTime string `json:"UploadTime"`
-	Uploader   string `json:"Uploader"`
-	Department string `json:"Department"`
+	ObjectType      string `json:"docType"`
+	InfoID          string `json:"InfoID"`
+	InfoType        string `json:"InfoType"`
+	ContentHash     string `json:"ContentHash"`
+	UploadTime      string `json:"UploadTime"`
+	Uploader        string `json:"Uploader"`
+	Department      string `json:"Department"`
+	CreatorMSP      string `json:"CreatorMSP"`
+	CreatorCN       string `json:"CreatorCN"`
+	CreatorCertHash string `json:"CreatorCertHash"`
 }
 
+// infoPrivateDetails is the sensitive payload, written with PutPrivateData into the
+// department's private data collection instead of the public ledger.
+type infoPrivateDetails struct {
+	InfoID  string `json:"InfoID"`
+	Content string `json:"Content"`
+}
+
+// privateCollectionName derives the private data collection for a department, e.g.
+// "airforce" -> "collectionInfo_airforce". The collection itself must be configured on
+// the channel (collections_config.json) with that department's orgs as members.
+func privateCollectionName(department string) string {
+	return "collectionInfo_" + department
+}
+
+// callerIdentity is the caller provenance derived from the transaction proposal's signing
+// certificate via the cid package
+type callerIdentity struct {
+	MSPID      string
+	CommonName string
+	Department string
+	CertHash   string
+}
+
+// infoshareRoleAttribute/infoshareUploaderRole gate the write paths behind an enrollment
+// attribute issued by the caller's MSP, e.g. via `fabric-ca-client register --id.attrs
+// "infoshare.role=uploader:ecert"`.
+const infoshareRoleAttribute = "infoshare.role"
+const infoshareUploaderRole = "uploader"
+
+// infoshareDepartmentAttribute carries the department the caller's MSP has authorized them
+// to act on behalf of.
+const infoshareDepartmentAttribute = "infoshare.department"
+
+// ====================================================================================
+// getCallerIdentity derives the caller's MSP ID, CN and authorized department from the
+// transaction proposal's signing certificate, and rejects callers missing the
+// infoshare.role=uploader attribute required to invoke a write path.
+// ====================================================================================
+func getCallerIdentity(stub shim.ChaincodeStubInterface) (*callerIdentity, error) {
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller MSP ID: %s", err)
+	}
+
+	cert, err := cid.GetX509Certificate(stub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller certificate: %s", err)
+	}
+
+	role, found, err := cid.GetAttributeValue(stub, infoshareRoleAttribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s attribute: %s", infoshareRoleAttribute, err)
+	}
+	if !found || role != infoshareUploaderRole {
+		return nil, fmt.Errorf("caller is missing the %s=%s attribute required to modify info", infoshareRoleAttribute, infoshareUploaderRole)
+	}
+
+	department, found, err := cid.GetAttributeValue(stub, infoshareDepartmentAttribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s attribute: %s", infoshareDepartmentAttribute, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("caller certificate is missing the %s attribute", infoshareDepartmentAttribute)
+	}
+
+	certHash := sha256.Sum256(cert.Raw)
+
+	return &callerIdentity{
+		MSPID:      mspID,
+		CommonName: cert.Subject.CommonName,
+		Department: strings.ToLower(department),
+		CertHash:   hex.EncodeToString(certHash[:]),
+	}, nil
+}
+
+// Composite-key secondary indexes, following the marbles sample. These let a peer backed
+// by LevelDB (no rich-query support) resolve "query by X" invocations via GetStateByPartialCompositeKey
+// instead of a CouchDB selector.
+const departmentUploaderIndex = "department~uploader~infoid"
+const infotypeIndex = "infotype~infoid"
+const uploaderIndex = "uploader~infoid"
+
 // ===================================================================================
 // Main
 // ===================================================================================
@@ -55,6 +149,30 @@ func (t *InfoShare) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		return t.queryInfoByUploader(stub, args)
 	} else if function == "queryInfoByInfoType" { //query an info by infotype
 		return t.queryInfoByInfoType(stub, args)
+	} else if function == "updateInfoContent" { //update the content of an info
+		return t.updateInfoContent(stub, args)
+	} else if function == "transferInfoOwnership" { //transfer uploader/department ownership of an info
+		return t.transferInfoOwnership(stub, args)
+	} else if function == "deleteInfo" { //delete an info
+		return t.deleteInfo(stub, args)
+	} else if function == "getHistoryForInfo" { //get the history of an info
+		return t.getHistoryForInfo(stub, args)
+	} else if function == "queryInfoByRange" { //query a range of infos by ID
+		return t.queryInfoByRange(stub, args)
+	} else if function == "queryInfoWithPagination" { //query infos with a rich query, paginated
+		return t.queryInfoWithPagination(stub, args)
+	} else if function == "queryInfoByRangeWithPagination" { //query a range of infos, paginated
+		return t.queryInfoByRangeWithPagination(stub, args)
+	} else if function == "queryInfoByDepartmentIndex" { //query an info by department, via the composite-key index
+		return t.queryInfoByDepartmentIndex(stub, args)
+	} else if function == "queryInfoByInfoTypeIndex" { //query an info by infotype, via the composite-key index
+		return t.queryInfoByInfoTypeIndex(stub, args)
+	} else if function == "queryInfoByUploaderIndex" { //query an info by uploader, via the composite-key index
+		return t.queryInfoByUploaderIndex(stub, args)
+	} else if function == "readInfoPrivateDetails" { //read the private Content for an info
+		return t.readInfoPrivateDetails(stub, args)
+	} else if function == "verifyInfoHash" { //verify the private Content against its public hash
+		return t.verifyInfoHash(stub, args)
 	}
 
 	fmt.Println("invoke did not find func: " + function) //error
@@ -67,10 +185,14 @@ func (t *InfoShare) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 func (t *InfoShare) initInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	var err error
 
-	//     0        1          2        3       4          5
-	// "420106","weather"", "sunny", "10:10", "bob",  "airforce"
-	if len(args) != 6 {
-		return shim.Error("Incorrect number of arguments. Expecting 6")
+	//     0        1          2        3
+	// "420106","weather"", "10:10", "bob",... Department below
+	//     4
+	// "airforce"
+	// The sensitive Content itself is never part of args - it travels via the transient
+	// map so it never appears in the transaction proposal or the public ledger.
+	if len(args) != 5 {
+		return shim.Error("Incorrect number of arguments. Expecting 5")
 	}
 
 	// ==== Input sanitation ====
@@ -90,15 +212,33 @@ func (t *InfoShare) initInfo(stub shim.ChaincodeStubInterface, args []string) pb
 	if len(args[4]) <= 0 {
 		return shim.Error("5th argument must be a non-empty string")
 	}
-	if len(args[5]) <= 0 {
-		return shim.Error("6th argument must be a non-empty string")
-	}
 	InfoID := args[0]
 	InfoType := strings.ToLower(args[1])
-	Content := args[2]
-	UploadTime := args[3]
-	Uploader := strings.ToLower(args[4])
-	Department := strings.ToLower(args[5])
+	UploadTime := args[2]
+	Uploader := strings.ToLower(args[3])
+	Department := strings.ToLower(args[4])
+
+	// ==== MSP-based access control ====
+	caller, err := getCallerIdentity(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if caller.Department != Department {
+		return shim.Error("caller is only authorized for department " + caller.Department + ", not " + Department)
+	}
+
+	// ==== Read the sensitive Content from the transient map ====
+	transientMap, err := stub.GetTransient()
+	if err != nil {
+		return shim.Error("Failed to get transient: " + err.Error())
+	}
+	Content, ok := transientMap["content"]
+	if !ok {
+		return shim.Error("content must be provided via the transient map under the \"content\" key")
+	}
+	if len(Content) <= 0 {
+		return shim.Error("content must be a non-empty value")
+	}
 
 	// ==== Check if info already exists ====
 	infoAsBytes, err := stub.GetState(InfoID)
@@ -108,25 +248,116 @@ func (t *InfoShare) initInfo(stub shim.ChaincodeStubInterface, args []string) pb
 		return shim.Error("This info already exists: " + InfoID)
 	}
 
+	contentHash := sha256.Sum256(Content)
+	ContentHash := hex.EncodeToString(contentHash[:])
+
 	// ==== Create info object and marshal to JSON ====
 	objectType := "info"
-	info := &info{objectType, InfoID, InfoType, Content, UploadTime, Uploader, Department}
+	info := &info{objectType, InfoID, InfoType, ContentHash, UploadTime, Uploader, Department, caller.MSPID, caller.CommonName, caller.CertHash}
 	infoJSONasBytes, err := json.Marshal(info)
 	if err != nil {
 		return shim.Error(err.Error())
 	}
 
-	// === Save info to state ===
+	// === Save public metadata to the channel ledger ===
 	err = stub.PutState(InfoID, infoJSONasBytes)
 	if err != nil {
 		return shim.Error(err.Error())
 	}
 
+	// === Save the sensitive Content to the department's private data collection ===
+	privateDetails := &infoPrivateDetails{InfoID, string(Content)}
+	privateDetailsAsBytes, err := json.Marshal(privateDetails)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	err = stub.PutPrivateData(privateCollectionName(Department), InfoID, privateDetailsAsBytes)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// ==== Index the info to enable department/uploader/infotype range queries ====
+	err = createInfoIndexes(stub, InfoID, InfoType, Uploader, Department)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = setInfoEvent(stub, EventInfoCreated, InfoID, InfoType, Department, Uploader)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
 	// ==== info saved and indexed. Return success ====
 	fmt.Println("- end init info")
 	return shim.Success(nil)
 }
 
+// ====================================================================================
+// createInfoIndexes writes the department~uploader~infoid, infotype~infoid and
+// uploader~infoid composite-key index entries for an info
+// ====================================================================================
+func createInfoIndexes(stub shim.ChaincodeStubInterface, InfoID string, InfoType string, Uploader string, Department string) error {
+	value := []byte{0x00}
+
+	departmentUploaderIndexKey, err := stub.CreateCompositeKey(departmentUploaderIndex, []string{Department, Uploader, InfoID})
+	if err != nil {
+		return err
+	}
+	if err := stub.PutState(departmentUploaderIndexKey, value); err != nil {
+		return err
+	}
+
+	infotypeIndexKey, err := stub.CreateCompositeKey(infotypeIndex, []string{InfoType, InfoID})
+	if err != nil {
+		return err
+	}
+	if err := stub.PutState(infotypeIndexKey, value); err != nil {
+		return err
+	}
+
+	uploaderIndexKey, err := stub.CreateCompositeKey(uploaderIndex, []string{Uploader, InfoID})
+	if err != nil {
+		return err
+	}
+	if err := stub.PutState(uploaderIndexKey, value); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ====================================================================================
+// deleteInfoIndexes removes the department~uploader~infoid, infotype~infoid and
+// uploader~infoid composite-key index entries for an info
+// ====================================================================================
+func deleteInfoIndexes(stub shim.ChaincodeStubInterface, InfoID string, InfoType string, Uploader string, Department string) error {
+	departmentUploaderIndexKey, err := stub.CreateCompositeKey(departmentUploaderIndex, []string{Department, Uploader, InfoID})
+	if err != nil {
+		return err
+	}
+	if err := stub.DelState(departmentUploaderIndexKey); err != nil {
+		return err
+	}
+
+	infotypeIndexKey, err := stub.CreateCompositeKey(infotypeIndex, []string{InfoType, InfoID})
+	if err != nil {
+		return err
+	}
+	if err := stub.DelState(infotypeIndexKey); err != nil {
+		return err
+	}
+
+	uploaderIndexKey, err := stub.CreateCompositeKey(uploaderIndex, []string{Uploader, InfoID})
+	if err != nil {
+		return err
+	}
+	if err := stub.DelState(uploaderIndexKey); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // ===============================================
 // readInfo - read an info from chaincode state
 // ===============================================
@@ -151,6 +382,408 @@ func (t *InfoShare) readInfo(stub shim.ChaincodeStubInterface, args []string) pb
 	return shim.Success(valAsbytes)
 }
 
+// ====================================================================================
+// updateInfoContent - update the content of an existing info. The new Content is read
+// from the transient map (same as initInfo), re-written to the private data collection,
+// and its hash re-marshaled into the public record so prior versions remain queryable via
+// getHistoryForInfo and tampering stays detectable via verifyInfoHash.
+// ====================================================================================
+func (t *InfoShare) updateInfoContent(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//   0
+	// "420106"
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting ID of the info to update")
+	}
+
+	InfoID := args[0]
+
+	fmt.Println("- start updateInfoContent")
+
+	infoAsBytes, err := stub.GetState(InfoID)
+	if err != nil {
+		return shim.Error("Failed to get info: " + err.Error())
+	} else if infoAsBytes == nil {
+		return shim.Error("This info does not exist: " + InfoID)
+	}
+
+	infoToUpdate := info{}
+	err = json.Unmarshal(infoAsBytes, &infoToUpdate)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// ==== MSP-based access control ====
+	caller, err := getCallerIdentity(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if caller.Department != infoToUpdate.Department {
+		return shim.Error("caller is only authorized for department " + caller.Department + ", not " + infoToUpdate.Department)
+	}
+
+	// ==== Read the new Content from the transient map ====
+	transientMap, err := stub.GetTransient()
+	if err != nil {
+		return shim.Error("Failed to get transient: " + err.Error())
+	}
+	newContent, ok := transientMap["content"]
+	if !ok {
+		return shim.Error("content must be provided via the transient map under the \"content\" key")
+	}
+	if len(newContent) <= 0 {
+		return shim.Error("content must be a non-empty value")
+	}
+
+	newContentHash := sha256.Sum256(newContent)
+	infoToUpdate.ContentHash = hex.EncodeToString(newContentHash[:])
+
+	infoJSONasBytes, err := json.Marshal(infoToUpdate)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = stub.PutState(InfoID, infoJSONasBytes)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	privateDetails := &infoPrivateDetails{InfoID, string(newContent)}
+	privateDetailsAsBytes, err := json.Marshal(privateDetails)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	err = stub.PutPrivateData(privateCollectionName(infoToUpdate.Department), InfoID, privateDetailsAsBytes)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = setInfoEvent(stub, EventInfoUpdated, InfoID, infoToUpdate.InfoType, infoToUpdate.Department, infoToUpdate.Uploader)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	fmt.Println("- end updateInfoContent")
+	return shim.Success(nil)
+}
+
+// ====================================================================================
+// transferInfoOwnership - change the Uploader and Department of an existing info
+// ====================================================================================
+func (t *InfoShare) transferInfoOwnership(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//   0          1        2
+	// "420106", "alice", "navy"
+	if len(args) != 3 {
+		return shim.Error("Incorrect number of arguments. Expecting 3")
+	}
+
+	// ==== Input sanitation ====
+	if len(args[0]) <= 0 {
+		return shim.Error("1st argument must be a non-empty string")
+	}
+	if len(args[1]) <= 0 {
+		return shim.Error("2nd argument must be a non-empty string")
+	}
+	if len(args[2]) <= 0 {
+		return shim.Error("3rd argument must be a non-empty string")
+	}
+
+	InfoID := args[0]
+	newUploader := strings.ToLower(args[1])
+	newDepartment := strings.ToLower(args[2])
+
+	fmt.Println("- start transferInfoOwnership")
+
+	infoAsBytes, err := stub.GetState(InfoID)
+	if err != nil {
+		return shim.Error("Failed to get info: " + err.Error())
+	} else if infoAsBytes == nil {
+		return shim.Error("This info does not exist: " + InfoID)
+	}
+
+	infoToTransfer := info{}
+	err = json.Unmarshal(infoAsBytes, &infoToTransfer)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// ==== MSP-based access control ====
+	caller, err := getCallerIdentity(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if caller.Department != infoToTransfer.Department {
+		return shim.Error("caller is only authorized for department " + caller.Department + ", not " + infoToTransfer.Department)
+	}
+
+	// ==== Drop the index entries keyed on the old Uploader/Department before they change ====
+	err = deleteInfoIndexes(stub, InfoID, infoToTransfer.InfoType, infoToTransfer.Uploader, infoToTransfer.Department)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	oldDepartment := infoToTransfer.Department
+
+	infoToTransfer.Uploader = newUploader
+	infoToTransfer.Department = newDepartment
+
+	infoJSONasBytes, err := json.Marshal(infoToTransfer)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = stub.PutState(InfoID, infoJSONasBytes)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// ==== The private data collection is derived from Department, so a department change
+	// moves the private payload to the new department's collection. The endorsing peer must
+	// be a member of the old department's collection policy to read it - if it isn't, fail
+	// the transfer rather than silently leaving newDepartment without its Content. ====
+	if newDepartment != oldDepartment {
+		privateDetailsAsBytes, err := stub.GetPrivateData(privateCollectionName(oldDepartment), InfoID)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if privateDetailsAsBytes == nil {
+			return shim.Error("Failed to read private details for " + InfoID + " from " + privateCollectionName(oldDepartment) +
+				" - the endorsing peer may not belong to the source department's collection policy")
+		}
+
+		err = stub.PutPrivateData(privateCollectionName(newDepartment), InfoID, privateDetailsAsBytes)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		err = stub.DelPrivateData(privateCollectionName(oldDepartment), InfoID)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+
+	// ==== Re-index the info under the new Uploader/Department ====
+	err = createInfoIndexes(stub, InfoID, infoToTransfer.InfoType, newUploader, newDepartment)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = setInfoEvent(stub, EventInfoTransferred, InfoID, infoToTransfer.InfoType, newDepartment, newUploader)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	fmt.Println("- end transferInfoOwnership")
+	return shim.Success(nil)
+}
+
+// ====================================================================================
+// deleteInfo - remove an info from chaincode state. GetHistoryForKey still surfaces the
+// deletion as a tombstone, so the audit trail is preserved.
+// ====================================================================================
+func (t *InfoShare) deleteInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting ID of the info to delete")
+	}
+	InfoID := args[0]
+
+	infoAsBytes, err := stub.GetState(InfoID)
+	if err != nil {
+		return shim.Error("Failed to get info: " + err.Error())
+	} else if infoAsBytes == nil {
+		return shim.Error("This info does not exist: " + InfoID)
+	}
+
+	infoToDelete := info{}
+	err = json.Unmarshal(infoAsBytes, &infoToDelete)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// ==== MSP-based access control ====
+	caller, err := getCallerIdentity(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if caller.Department != infoToDelete.Department {
+		return shim.Error("caller is only authorized for department " + caller.Department + ", not " + infoToDelete.Department)
+	}
+
+	// ==== Remove the composite-key index entries so they don't drift from the primary record ====
+	err = deleteInfoIndexes(stub, InfoID, infoToDelete.InfoType, infoToDelete.Uploader, infoToDelete.Department)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = stub.DelState(InfoID)
+	if err != nil {
+		return shim.Error("Failed to delete info: " + err.Error())
+	}
+
+	err = stub.DelPrivateData(privateCollectionName(infoToDelete.Department), InfoID)
+	if err != nil {
+		return shim.Error("Failed to delete private details: " + err.Error())
+	}
+
+	err = setInfoEvent(stub, EventInfoDeleted, InfoID, infoToDelete.InfoType, infoToDelete.Department, infoToDelete.Uploader)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+// ====================================================================================
+// readInfoPrivateDetails - read the sensitive Content for an info from its department's
+// private data collection
+// ====================================================================================
+func (t *InfoShare) readInfoPrivateDetails(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting ID of the info to query")
+	}
+	InfoID := args[0]
+
+	infoAsBytes, err := stub.GetState(InfoID)
+	if err != nil {
+		return shim.Error("Failed to get info: " + err.Error())
+	} else if infoAsBytes == nil {
+		return shim.Error("This info does not exist: " + InfoID)
+	}
+
+	existingInfo := info{}
+	err = json.Unmarshal(infoAsBytes, &existingInfo)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	privateDetailsAsBytes, err := stub.GetPrivateData(privateCollectionName(existingInfo.Department), InfoID)
+	if err != nil {
+		return shim.Error("Failed to get private details: " + err.Error())
+	} else if privateDetailsAsBytes == nil {
+		return shim.Error("No private details for info: " + InfoID)
+	}
+
+	return shim.Success(privateDetailsAsBytes)
+}
+
+// ====================================================================================
+// verifyInfoHash - recompute the SHA-256 hash of the private Content and compare it
+// against the ContentHash on the public record, so tampering with the private payload is
+// detectable
+// ====================================================================================
+func (t *InfoShare) verifyInfoHash(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting ID of the info to verify")
+	}
+	InfoID := args[0]
+
+	infoAsBytes, err := stub.GetState(InfoID)
+	if err != nil {
+		return shim.Error("Failed to get info: " + err.Error())
+	} else if infoAsBytes == nil {
+		return shim.Error("This info does not exist: " + InfoID)
+	}
+
+	existingInfo := info{}
+	err = json.Unmarshal(infoAsBytes, &existingInfo)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	privateDetailsAsBytes, err := stub.GetPrivateData(privateCollectionName(existingInfo.Department), InfoID)
+	if err != nil {
+		return shim.Error("Failed to get private details: " + err.Error())
+	} else if privateDetailsAsBytes == nil {
+		return shim.Error("No private details for info: " + InfoID)
+	}
+
+	privateDetails := infoPrivateDetails{}
+	err = json.Unmarshal(privateDetailsAsBytes, &privateDetails)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	computedHash := sha256.Sum256([]byte(privateDetails.Content))
+	verified := hex.EncodeToString(computedHash[:]) == existingInfo.ContentHash
+
+	verifyResponse := struct {
+		InfoID   string `json:"InfoID"`
+		Verified bool   `json:"Verified"`
+	}{InfoID, verified}
+
+	verifyResponseAsBytes, err := json.Marshal(verifyResponse)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(verifyResponseAsBytes)
+}
+
+// ====================================================================================
+// getHistoryForInfo - return the full change history for an info as a JSON array of
+// {TxId, Timestamp, IsDelete, Value} entries, as reported by GetHistoryForKey
+// ====================================================================================
+func (t *InfoShare) getHistoryForInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting ID of the info to query")
+	}
+
+	InfoID := args[0]
+
+	fmt.Printf("- start getHistoryForInfo: %s\n", InfoID)
+
+	resultsIterator, err := stub.GetHistoryForKey(InfoID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	// buffer is a JSON array containing historic values for the info
+	var buffer bytes.Buffer
+	buffer.WriteString("[")
+
+	bArrayMemberAlreadyWritten := false
+	for resultsIterator.HasNext() {
+		response, err := resultsIterator.Next()
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		// Add a comma before array members, suppress it for the first array member
+		if bArrayMemberAlreadyWritten == true {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("{\"TxId\":")
+		buffer.WriteString("\"")
+		buffer.WriteString(response.TxId)
+		buffer.WriteString("\"")
+
+		buffer.WriteString(", \"Timestamp\":")
+		buffer.WriteString("\"")
+		buffer.WriteString(time.Unix(response.Timestamp.Seconds, int64(response.Timestamp.Nanos)).String())
+		buffer.WriteString("\"")
+
+		buffer.WriteString(", \"IsDelete\":")
+		buffer.WriteString("\"")
+		buffer.WriteString(strconv.FormatBool(response.IsDelete))
+		buffer.WriteString("\"")
+
+		buffer.WriteString(", \"Value\":")
+		// if it was a delete operation on the given key, set the corresponding
+		// value null. Otherwise write response.Value as-is (it is itself JSON)
+		if response.IsDelete {
+			buffer.WriteString("null")
+		} else {
+			buffer.WriteString(string(response.Value))
+		}
+
+		buffer.WriteString("}")
+		bArrayMemberAlreadyWritten = true
+	}
+	buffer.WriteString("]")
+
+	fmt.Printf("- getHistoryForInfo returning:\n%s\n", buffer.String())
+
+	return shim.Success(buffer.Bytes())
+}
+
 // =======Rich queries =========================================================================
 // Two examples of rich queries are provided below (parameterized query and ad hoc query).
 // Rich queries pass a query string to the state database.
@@ -226,6 +859,223 @@ func (t *InfoShare) queryInfoByInfoType(stub shim.ChaincodeStubInterface, args [
 	return shim.Success(queryResults)
 }
 
+// =========================================================================================
+// queryInfoByRange queries a range of infos by ID using GetStateByRange. Unlike the rich
+// queries above, this works on any state database implementation, including LevelDB.
+// =========================================================================================
+func (t *InfoShare) queryInfoByRange(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	//     0          1
+	// "420100", "420200"
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2")
+	}
+
+	startID := args[0]
+	endID := args[1]
+
+	resultsIterator, err := stub.GetStateByRange(startID, endID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	buffer, err := constructQueryResponseFromIterator(resultsIterator)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(buffer.Bytes())
+}
+
+// =========================================================================================
+// queryInfoWithPagination executes a CouchDB rich query with pagination, so a client can
+// iterate a large result set page by page instead of receiving every match at once.
+// =========================================================================================
+func (t *InfoShare) queryInfoWithPagination(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	//             0                1         2
+	// "{selector:{...}}"        , "10",   "bookmark"
+	if len(args) != 3 {
+		return shim.Error("Incorrect number of arguments. Expecting 3")
+	}
+
+	queryString := args[0]
+	pageSize, err := strconv.ParseInt(args[1], 10, 32)
+	if err != nil {
+		return shim.Error("2nd argument (pageSize) must be an integer: " + err.Error())
+	}
+	bookmark := args[2]
+
+	queryResults, err := getQueryResultForQueryStringWithPagination(stub, queryString, int32(pageSize), bookmark)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(queryResults)
+}
+
+// =========================================================================================
+// queryInfoByRangeWithPagination queries a range of infos by ID using GetStateByRangeWithPagination,
+// so a client can iterate a large range of IDs page by page.
+// =========================================================================================
+func (t *InfoShare) queryInfoByRangeWithPagination(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	//    0         1        2        3
+	// "420100", "420200", "10",  "bookmark"
+	if len(args) != 4 {
+		return shim.Error("Incorrect number of arguments. Expecting 4")
+	}
+
+	startID := args[0]
+	endID := args[1]
+	pageSize, err := strconv.ParseInt(args[2], 10, 32)
+	if err != nil {
+		return shim.Error("3rd argument (pageSize) must be an integer: " + err.Error())
+	}
+	bookmark := args[3]
+
+	resultsIterator, responseMetadata, err := stub.GetStateByRangeWithPagination(startID, endID, int32(pageSize), bookmark)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	queryResults, err := constructPaginatedQueryResponse(resultsIterator, responseMetadata)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(queryResults)
+}
+
+// =========================================================================================
+// queryInfoByDepartmentIndex resolves infos for a department via the
+// department~uploader~infoid composite-key index, so it works on LevelDB peers too.
+// =========================================================================================
+func (t *InfoShare) queryInfoByDepartmentIndex(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	//     0
+	// "airforce"
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1")
+	}
+
+	department := strings.ToLower(args[0])
+
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(departmentUploaderIndex, []string{department})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	buffer, err := constructQueryResponseFromCompositeKeyIterator(stub, resultsIterator)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(buffer.Bytes())
+}
+
+// =========================================================================================
+// queryInfoByInfoTypeIndex resolves infos for an infotype via the infotype~infoid
+// composite-key index, so it works on LevelDB peers too.
+// =========================================================================================
+func (t *InfoShare) queryInfoByInfoTypeIndex(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	//     0
+	// "weather"
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1")
+	}
+
+	infotype := strings.ToLower(args[0])
+
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(infotypeIndex, []string{infotype})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	buffer, err := constructQueryResponseFromCompositeKeyIterator(stub, resultsIterator)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(buffer.Bytes())
+}
+
+// =========================================================================================
+// queryInfoByUploaderIndex resolves infos for an uploader via the uploader~infoid
+// composite-key index, so it works on LevelDB peers too.
+// =========================================================================================
+func (t *InfoShare) queryInfoByUploaderIndex(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	//   0
+	// "bob"
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1")
+	}
+
+	uploader := strings.ToLower(args[0])
+
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(uploaderIndex, []string{uploader})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	buffer, err := constructQueryResponseFromCompositeKeyIterator(stub, resultsIterator)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(buffer.Bytes())
+}
+
+// ===========================================================================================
+// constructQueryResponseFromCompositeKeyIterator resolves each composite-key index entry back
+// to its InfoID via SplitCompositeKey, fetches the primary record, and constructs a JSON array
+// in the same {Key, Record} shape as constructQueryResponseFromIterator.
+// ===========================================================================================
+func constructQueryResponseFromCompositeKeyIterator(stub shim.ChaincodeStubInterface, resultsIterator shim.StateQueryIteratorInterface) (*bytes.Buffer, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("[")
+
+	bArrayMemberAlreadyWritten := false
+	for resultsIterator.HasNext() {
+		responseRange, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, compositeKeyParts, err := stub.SplitCompositeKey(responseRange.Key)
+		if err != nil {
+			return nil, err
+		}
+		InfoID := compositeKeyParts[len(compositeKeyParts)-1]
+
+		infoAsBytes, err := stub.GetState(InfoID)
+		if err != nil {
+			return nil, err
+		} else if infoAsBytes == nil {
+			continue
+		}
+
+		// Add a comma before array members, suppress it for the first array member
+		if bArrayMemberAlreadyWritten == true {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("{\"Key\":")
+		buffer.WriteString("\"")
+		buffer.WriteString(InfoID)
+		buffer.WriteString("\"")
+
+		buffer.WriteString(", \"Record\":")
+		buffer.WriteString(string(infoAsBytes))
+		buffer.WriteString("}")
+		bArrayMemberAlreadyWritten = true
+	}
+	buffer.WriteString("]")
+
+	return &buffer, nil
+}
+
 // ===========================================================================================
 // constructQueryResponseFromIterator constructs a JSON array containing query results from
 // a given result iterator
@@ -284,3 +1134,45 @@ func getQueryResultForQueryString(stub shim.ChaincodeStubInterface, queryString
 
 	return buffer.Bytes(), nil
 }
+
+// =========================================================================================
+// getQueryResultForQueryStringWithPagination executes the passed in query string with
+// pagination. Result set is built using the same constructQueryResponseFromIterator helper
+// as the non-paginated path, then wrapped with the FetchedRecordsCount/Bookmark metadata.
+// =========================================================================================
+func getQueryResultForQueryStringWithPagination(stub shim.ChaincodeStubInterface, queryString string, pageSize int32, bookmark string) ([]byte, error) {
+
+	fmt.Printf("- getQueryResultForQueryStringWithPagination queryString:\n%s\n", queryString)
+
+	resultsIterator, responseMetadata, err := stub.GetQueryResultWithPagination(queryString, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	return constructPaginatedQueryResponse(resultsIterator, responseMetadata)
+}
+
+// ===========================================================================================
+// constructPaginatedQueryResponse builds the JSON object returned to pagination-aware callers:
+// the records array (built via constructQueryResponseFromIterator) plus the FetchedRecordsCount
+// and Bookmark needed to fetch the next page.
+// ===========================================================================================
+func constructPaginatedQueryResponse(resultsIterator shim.StateQueryIteratorInterface, responseMetadata *pb.QueryResponseMetadata) ([]byte, error) {
+	buffer, err := constructQueryResponseFromIterator(resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+
+	paginatedResponse := struct {
+		Records             json.RawMessage `json:"Records"`
+		FetchedRecordsCount int32           `json:"FetchedRecordsCount"`
+		Bookmark            string          `json:"Bookmark"`
+	}{
+		Records:             json.RawMessage(buffer.Bytes()),
+		FetchedRecordsCount: responseMetadata.GetFetchedRecordsCount(),
+		Bookmark:            responseMetadata.GetBookmark(),
+	}
+
+	return json.Marshal(paginatedResponse)
+}